@@ -0,0 +1,70 @@
+package blockdb
+
+import (
+	"encoding/binary"
+	"sync/atomic"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+const (
+	// bloomFilterSize is the number of counters in the filter, sized for a
+	// large expected UTXO set. It trades memory for a low false-positive
+	// rate rather than trying to track the pool's exact size.
+	bloomFilterSize = 1 << 22
+	// bloomFilterK is the number of hash functions used per lookup/insert.
+	bloomFilterK = 4
+)
+
+// bloomFilter is a counting bloom filter over uxout hashes. Unlike a plain
+// bloom filter it supports removals, which the unspent pool needs whenever
+// an output is spent. Inserts and removals are lock-free so Collides and
+// Contains can probe it without taking the pool mutex; only a positive hit
+// falls through to the authoritative map lookup under the lock.
+type bloomFilter struct {
+	counts []uint32
+}
+
+func newBloomFilter() *bloomFilter {
+	return &bloomFilter{counts: make([]uint32, bloomFilterSize)}
+}
+
+// positions returns the bloomFilterK counter indices for h, derived from the
+// hash itself via the double-hashing technique (Kirsch-Mitzenmacher) so no
+// extra hashing is needed per function.
+func (f *bloomFilter) positions(h cipher.SHA256) [bloomFilterK]uint32 {
+	a := binary.BigEndian.Uint64(h[0:8])
+	b := binary.BigEndian.Uint64(h[8:16])
+
+	var positions [bloomFilterK]uint32
+	for i := 0; i < bloomFilterK; i++ {
+		positions[i] = uint32((a + uint64(i)*b) % uint64(len(f.counts)))
+	}
+	return positions
+}
+
+// add increments the counters for h. Safe for concurrent use.
+func (f *bloomFilter) add(h cipher.SHA256) {
+	for _, p := range f.positions(h) {
+		atomic.AddUint32(&f.counts[p], 1)
+	}
+}
+
+// remove decrements the counters for h. Safe for concurrent use.
+func (f *bloomFilter) remove(h cipher.SHA256) {
+	for _, p := range f.positions(h) {
+		atomic.AddUint32(&f.counts[p], ^uint32(0))
+	}
+}
+
+// mayContain returns false if h is definitely not in the filter, and true
+// if it might be. A true result must be confirmed against the authoritative
+// map, since counting bloom filters can still false-positive.
+func (f *bloomFilter) mayContain(h cipher.SHA256) bool {
+	for _, p := range f.positions(h) {
+		if atomic.LoadUint32(&f.counts[p]) == 0 {
+			return false
+		}
+	}
+	return true
+}
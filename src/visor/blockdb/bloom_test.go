@@ -0,0 +1,56 @@
+package blockdb
+
+import (
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBloomFilterAddRemove(t *testing.T) {
+	f := newBloomFilter()
+
+	addr := testAddress(t)
+	ux := makeUxOut(addr, 1)
+	h := ux.Hash()
+
+	require.False(t, f.mayContain(h))
+
+	f.add(h)
+	require.True(t, f.mayContain(h))
+
+	f.remove(h)
+	require.False(t, f.mayContain(h))
+}
+
+func TestUnspentPoolCollidesContains(t *testing.T) {
+	db, cleanup := newTestDB(t)
+	defer cleanup()
+
+	up, err := NewUnspentPool(db)
+	require.NoError(t, err)
+
+	addr := testAddress(t)
+	ux := makeUxOut(addr, 1)
+
+	require.False(t, up.Contains(ux.Hash()))
+	require.False(t, up.Collides([]cipher.SHA256{ux.Hash()}))
+
+	require.NoError(t, db.Update(func(tx *bolt.Tx) error {
+		up.Lock()
+		defer up.Unlock()
+		return up.addUx(tx, ux)
+	}))
+
+	require.True(t, up.Contains(ux.Hash()))
+	require.True(t, up.Collides([]cipher.SHA256{ux.Hash()}))
+
+	require.NoError(t, db.Update(func(tx *bolt.Tx) error {
+		up.Lock()
+		defer up.Unlock()
+		return up.deleteUx(tx, ux, 1)
+	}))
+
+	require.False(t, up.Contains(ux.Hash()))
+}
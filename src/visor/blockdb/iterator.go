@@ -0,0 +1,126 @@
+package blockdb
+
+import (
+	"bytes"
+
+	"github.com/boltdb/bolt"
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// ForEach calls f for every uxout in the pool, iterating via a read-only
+// bolt cursor so the pool's write mutex is never held for the duration and
+// the whole set never needs to be materialized at once. If f returns an
+// error, iteration stops and that error is returned.
+func (up *UnspentPool) ForEach(f func(coin.UxOut) error) error {
+	return up.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(up.pool.Name).ForEach(func(k, v []byte) error {
+			var ux coin.UxOut
+			if err := encoder.DeserializeRaw(v, &ux); err != nil {
+				return err
+			}
+			return f(ux)
+		})
+	})
+}
+
+// UxIterator iterates over the unspent pool using a single read-only bolt
+// transaction and cursor, so callers can page through large pools without
+// materializing the whole set or holding the pool's write mutex.
+type UxIterator struct {
+	tx      *bolt.Tx
+	cursor  *bolt.Cursor
+	started bool
+	ux      coin.UxOut
+	err     error
+
+	// seeked, when true, means Next must consume the key/value Seek
+	// already landed on before it resumes advancing the cursor.
+	seeked    bool
+	seekExact bool
+	seekKey   []byte
+	seekVal   []byte
+}
+
+// Iterator returns a UxIterator over the pool. The caller must call Close
+// when done to release the underlying bolt transaction.
+func (up *UnspentPool) Iterator() (*UxIterator, error) {
+	tx, err := up.db.Begin(false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UxIterator{
+		tx:     tx,
+		cursor: tx.Bucket(up.pool.Name).Cursor(),
+	}, nil
+}
+
+// Seek positions the iterator so the next call to Next resumes from hash.
+// Callers paging through the pool a page at a time can pass the hash of
+// the last uxout from the previous page to resume from there instead of
+// restarting from the beginning. If hash was spent since the previous
+// page was read, Next returns the first surviving key at or after hash
+// rather than skipping it; otherwise it returns the key after hash, since
+// hash itself was already returned on the previous page.
+func (it *UxIterator) Seek(hash cipher.SHA256) {
+	it.started = true
+	it.seeked = true
+	it.seekKey, it.seekVal = it.cursor.Seek(hash[:])
+	it.seekExact = it.seekKey != nil && bytes.Equal(it.seekKey, hash[:])
+}
+
+// Next advances the iterator and reports whether a value is available via
+// UxOut. It returns false once iteration is exhausted or an error occurs;
+// call Err to distinguish the two.
+func (it *UxIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	var k, v []byte
+	switch {
+	case it.seeked:
+		it.seeked = false
+		if it.seekExact {
+			k, v = it.cursor.Next()
+		} else {
+			k, v = it.seekKey, it.seekVal
+		}
+	case !it.started:
+		it.started = true
+		k, v = it.cursor.First()
+	default:
+		k, v = it.cursor.Next()
+	}
+
+	if k == nil {
+		return false
+	}
+
+	var ux coin.UxOut
+	if err := encoder.DeserializeRaw(v, &ux); err != nil {
+		it.err = err
+		return false
+	}
+
+	it.ux = ux
+	return true
+}
+
+// UxOut returns the uxout at the iterator's current position. Valid only
+// after a call to Next that returned true.
+func (it *UxIterator) UxOut() coin.UxOut {
+	return it.ux
+}
+
+// Err returns the first error encountered during iteration, if any.
+func (it *UxIterator) Err() error {
+	return it.err
+}
+
+// Close releases the iterator's underlying bolt transaction.
+func (it *UxIterator) Close() error {
+	return it.tx.Rollback()
+}
@@ -0,0 +1,133 @@
+package blockdb
+
+import (
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnspentPoolForEach(t *testing.T) {
+	db, cleanup := newTestDB(t)
+	defer cleanup()
+
+	up, err := NewUnspentPool(db)
+	require.NoError(t, err)
+
+	addr := testAddress(t)
+	ux1 := makeUxOut(addr, 1)
+	ux2 := makeUxOut(addr, 2)
+
+	require.NoError(t, db.Update(func(tx *bolt.Tx) error {
+		up.Lock()
+		defer up.Unlock()
+		if err := up.addUx(tx, ux1); err != nil {
+			return err
+		}
+		return up.addUx(tx, ux2)
+	}))
+
+	seen := map[cipher.SHA256]bool{}
+	require.NoError(t, up.ForEach(func(ux coin.UxOut) error {
+		seen[ux.Hash()] = true
+		return nil
+	}))
+
+	require.True(t, seen[ux1.Hash()])
+	require.True(t, seen[ux2.Hash()])
+	require.Len(t, seen, 2)
+}
+
+func TestUxIteratorSeek(t *testing.T) {
+	db, cleanup := newTestDB(t)
+	defer cleanup()
+
+	up, err := NewUnspentPool(db)
+	require.NoError(t, err)
+
+	addr := testAddress(t)
+	ux1 := makeUxOut(addr, 1)
+	ux2 := makeUxOut(addr, 2)
+
+	require.NoError(t, db.Update(func(tx *bolt.Tx) error {
+		up.Lock()
+		defer up.Unlock()
+		if err := up.addUx(tx, ux1); err != nil {
+			return err
+		}
+		return up.addUx(tx, ux2)
+	}))
+
+	it, err := up.Iterator()
+	require.NoError(t, err)
+	defer it.Close()
+
+	require.True(t, it.Next())
+	firstHash := it.UxOut().Hash()
+
+	// Resume from the first page's cursor; the second page should pick up
+	// where the first left off instead of starting over.
+	it.Seek(firstHash)
+	require.True(t, it.Next())
+	require.NotEqual(t, firstHash, it.UxOut().Hash())
+	require.False(t, it.Next())
+	require.NoError(t, it.Err())
+}
+
+// TestUxIteratorSeekAfterDeleted checks that resuming from a hash that was
+// spent since the previous page was read returns the next surviving
+// uxout instead of silently skipping over it.
+func TestUxIteratorSeekAfterDeleted(t *testing.T) {
+	db, cleanup := newTestDB(t)
+	defer cleanup()
+
+	up, err := NewUnspentPool(db)
+	require.NoError(t, err)
+
+	addr := testAddress(t)
+	uxs := []coin.UxOut{makeUxOut(addr, 1), makeUxOut(addr, 2), makeUxOut(addr, 3)}
+
+	require.NoError(t, db.Update(func(tx *bolt.Tx) error {
+		up.Lock()
+		defer up.Unlock()
+		for _, ux := range uxs {
+			if err := up.addUx(tx, ux); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+
+	// Discover bolt's key order (by hash) so the test doesn't depend on
+	// insertion order.
+	var order []cipher.SHA256
+	require.NoError(t, up.ForEach(func(ux coin.UxOut) error {
+		order = append(order, ux.Hash())
+		return nil
+	}))
+	require.Len(t, order, 3)
+
+	// Spend the middle uxout between pages.
+	require.NoError(t, db.Update(func(tx *bolt.Tx) error {
+		up.Lock()
+		defer up.Unlock()
+		for _, ux := range uxs {
+			if ux.Hash() == order[1] {
+				return up.deleteUx(tx, ux, 1)
+			}
+		}
+		return nil
+	}))
+
+	it, err := up.Iterator()
+	require.NoError(t, err)
+	defer it.Close()
+
+	it.Seek(order[1])
+	require.True(t, it.Next())
+	require.Equal(t, order[2], it.UxOut().Hash())
+	require.False(t, it.Next())
+	require.NoError(t, it.Err())
+}
@@ -0,0 +1,88 @@
+package blockdb
+
+import (
+	"github.com/boltdb/bolt"
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// journalEntry is a single reversible mutation applied to an UnspentPool.
+// Reverting an entry restores both the in-memory cache and the on-disk
+// bolt state, so a block or transaction can be undone without relying on
+// the surrounding bolt transaction to also roll back the cache and xorhash.
+type journalEntry interface {
+	// revert undoes the entry inside tx, which must be a writable
+	// transaction over the pool's buckets.
+	revert(up *UnspentPool, tx *bolt.Tx) error
+}
+
+// journal is an ordered log of reversible mutations. Entries are appended
+// as the pool is mutated and replayed in reverse by RevertToSnapshot to
+// roll back to an earlier state. generation is bumped every time the
+// journal is reset, so a SnapshotID taken before a reset can be recognized
+// as stale instead of being replayed against entries it was never part of.
+type journal struct {
+	entries    []journalEntry
+	generation int
+}
+
+func (j *journal) append(entry journalEntry) {
+	j.entries = append(j.entries, entry)
+}
+
+func (j *journal) length() int {
+	return len(j.entries)
+}
+
+// reset discards every entry and bumps the generation, invalidating any
+// SnapshotID taken against the entries it held.
+func (j *journal) reset() {
+	j.entries = nil
+	j.generation++
+}
+
+// uxAddedJournal records that ux was inserted into the pool
+type uxAddedJournal struct {
+	ux coin.UxOut
+}
+
+func (e uxAddedJournal) revert(up *UnspentPool, tx *bolt.Tx) error {
+	h := e.ux.Hash()
+
+	up.deleteUxFromCache([]coin.UxOut{e.ux})
+
+	if err := (uxOuts{tx.Bucket(up.pool.Name)}).delete(h); err != nil {
+		return err
+	}
+
+	return (addrUxIndex{tx.Bucket(up.addrIndex.Name)}).remove(e.ux.Body.Address, h)
+}
+
+// uxDeletedJournal records that ux was removed from the pool
+type uxDeletedJournal struct {
+	ux coin.UxOut
+}
+
+func (e uxDeletedJournal) revert(up *UnspentPool, tx *bolt.Tx) error {
+	h := e.ux.Hash()
+
+	up.addUxToCache([]coin.UxOut{e.ux})
+
+	if err := (uxOuts{tx.Bucket(up.pool.Name)}).set(h, e.ux); err != nil {
+		return err
+	}
+
+	return (addrUxIndex{tx.Bucket(up.addrIndex.Name)}).add(e.ux.Body.Address, h)
+}
+
+// xorHashJournal records a change to the pool's xorhash checksum
+type xorHashJournal struct {
+	prev cipher.SHA256
+}
+
+func (e xorHashJournal) revert(up *UnspentPool, tx *bolt.Tx) error {
+	up.updateUxHashInCache(e.prev)
+
+	meta := unspentMeta{tx.Bucket(up.meta.Name)}
+	return meta.setXorHash(e.prev)
+}
@@ -0,0 +1,155 @@
+package blockdb
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSnapshotRevert checks that RevertToSnapshot undoes a mutation in both
+// the cache and the bolt state.
+func TestSnapshotRevert(t *testing.T) {
+	db, cleanup := newTestDB(t)
+	defer cleanup()
+
+	up, err := NewUnspentPool(db)
+	require.NoError(t, err)
+
+	addr := testAddress(t)
+	ux := makeUxOut(addr, 1)
+
+	snapshot := up.Snapshot()
+
+	require.NoError(t, db.Update(func(tx *bolt.Tx) error {
+		up.Lock()
+		defer up.Unlock()
+		return up.addUx(tx, ux)
+	}))
+
+	_, ok := up.Get(ux.Hash())
+	require.True(t, ok)
+
+	require.NoError(t, up.RevertToSnapshot(snapshot))
+
+	_, ok = up.Get(ux.Hash())
+	require.False(t, ok)
+	require.Equal(t, uint64(0), up.Len())
+}
+
+// TestProcessBlockRollback checks that the Rollback closure returned by
+// processBlock can be called while the block's own transaction is still
+// open, as happens when a later handler in the same aggregate update
+// fails, and that it reverts the pool back to its pre-block state.
+func TestProcessBlockRollback(t *testing.T) {
+	db, cleanup := newTestDB(t)
+	defer cleanup()
+
+	up, err := NewUnspentPool(db)
+	require.NoError(t, err)
+
+	addr := testAddress(t)
+	ux := makeUxOut(addr, 1)
+
+	require.NoError(t, db.Update(func(tx *bolt.Tx) error {
+		up.Lock()
+		defer up.Unlock()
+		return up.addUx(tx, ux)
+	}))
+
+	blk := &coin.Block{}
+	blk.Head.BkSeq = 1
+	blk.Body.Transactions = coin.Transactions{
+		coin.Transaction{In: []cipher.SHA256{ux.Hash()}},
+	}
+
+	simulatedErr := errors.New("a later handler in this update failed")
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		rollback, err := up.processBlock(blk)(tx)
+		require.NoError(t, err)
+
+		// The block's own transaction is still open here, same as when a
+		// sibling handler fails inside the same aggregate update.
+		rollback()
+
+		return simulatedErr
+	})
+	require.Equal(t, simulatedErr, err)
+
+	reverted, ok := up.Get(ux.Hash())
+	require.True(t, ok)
+	require.Equal(t, ux.Hash(), reverted.Hash())
+	require.Equal(t, uint64(1), up.Len())
+}
+
+// TestProcessBlockMidBlockErrorRevertsWholeBlock checks that a failure on a
+// later transaction in a block reverts every transaction already applied
+// earlier in that same block, not just the failing one.
+func TestProcessBlockMidBlockErrorRevertsWholeBlock(t *testing.T) {
+	db, cleanup := newTestDB(t)
+	defer cleanup()
+
+	up, err := NewUnspentPool(db)
+	require.NoError(t, err)
+
+	addr := testAddress(t)
+	ux1 := makeUxOut(addr, 1)
+
+	require.NoError(t, db.Update(func(tx *bolt.Tx) error {
+		up.Lock()
+		defer up.Unlock()
+		return up.addUx(tx, ux1)
+	}))
+
+	var unknownHash cipher.SHA256
+	unknownHash[0] = 0xff
+
+	blk := &coin.Block{}
+	blk.Head.BkSeq = 1
+	blk.Body.Transactions = coin.Transactions{
+		// Spends ux1, succeeds.
+		coin.Transaction{In: []cipher.SHA256{ux1.Hash()}},
+		// References a uxout that doesn't exist, fails.
+		coin.Transaction{In: []cipher.SHA256{unknownHash}},
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := up.processBlock(blk)(tx)
+		return err
+	})
+	require.Error(t, err)
+
+	// ux1's deletion from the first transaction must have been reverted
+	// along with the second transaction's failed attempt, even though
+	// only the second transaction itself failed.
+	reverted, ok := up.Get(ux1.Hash())
+	require.True(t, ok)
+	require.Equal(t, ux1.Hash(), reverted.Hash())
+	require.Equal(t, uint64(1), up.Len())
+}
+
+// TestRevertToSnapshotStaleGeneration checks that a SnapshotID taken before
+// the journal was reset (e.g. by the next block starting) is rejected
+// instead of panicking or replaying against the wrong entries.
+func TestRevertToSnapshotStaleGeneration(t *testing.T) {
+	db, cleanup := newTestDB(t)
+	defer cleanup()
+
+	up, err := NewUnspentPool(db)
+	require.NoError(t, err)
+
+	stale := up.Snapshot()
+
+	blk := &coin.Block{}
+	blk.Head.BkSeq = 1
+	require.NoError(t, db.Update(func(tx *bolt.Tx) error {
+		_, err := up.processBlock(blk)(tx)
+		return err
+	}))
+
+	require.Error(t, up.RevertToSnapshot(stale))
+}
@@ -0,0 +1,71 @@
+package blockdb
+
+import (
+	"container/list"
+
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// uxLRU is a fixed-size, most-recently-used cache of uxouts keyed by hash
+// hex string, used to bound UnspentPool's memory use under ModeLRU. It is
+// not safe for concurrent use; callers must hold UnspentPool's mutex.
+type uxLRU struct {
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+type uxLRUEntry struct {
+	key string
+	ux  coin.UxOut
+}
+
+func newUxLRU(capacity int) *uxLRU {
+	return &uxLRU{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element, capacity),
+	}
+}
+
+func (c *uxLRU) get(key string) (coin.UxOut, bool) {
+	elem, ok := c.items[key]
+	if !ok {
+		return coin.UxOut{}, false
+	}
+
+	c.ll.MoveToFront(elem)
+	return elem.Value.(*uxLRUEntry).ux, true
+}
+
+func (c *uxLRU) add(key string, ux coin.UxOut) {
+	if elem, ok := c.items[key]; ok {
+		elem.Value.(*uxLRUEntry).ux = ux
+		c.ll.MoveToFront(elem)
+		return
+	}
+
+	elem := c.ll.PushFront(&uxLRUEntry{key: key, ux: ux})
+	c.items[key] = elem
+
+	if c.ll.Len() > c.capacity {
+		c.removeOldest()
+	}
+}
+
+func (c *uxLRU) remove(key string) {
+	if elem, ok := c.items[key]; ok {
+		c.ll.Remove(elem)
+		delete(c.items, key)
+	}
+}
+
+func (c *uxLRU) removeOldest() {
+	elem := c.ll.Back()
+	if elem == nil {
+		return
+	}
+
+	c.ll.Remove(elem)
+	delete(c.items, elem.Value.(*uxLRUEntry).key)
+}
@@ -0,0 +1,41 @@
+package blockdb
+
+// Mode controls how much of the unspent pool is kept resident in memory.
+type Mode int
+
+const (
+	// ModeFull keeps the entire live unspent set in memory, backed by bolt.
+	// This is the original behavior and the default.
+	ModeFull Mode = iota
+	// ModeLRU keeps only a bounded, most-recently-used subset of the live
+	// unspent set in memory; misses fall through to bolt and repopulate
+	// the LRU. Use this on memory-constrained nodes.
+	ModeLRU
+	// ModeArchive behaves like ModeFull but additionally retains spent
+	// outputs, tagged with the block height at which they were spent, in
+	// a separate bucket so historical balances can be reconstructed
+	// without replaying the chain.
+	ModeArchive
+)
+
+// defaultLRUSize is used when ModeLRU is selected without an explicit
+// WithLRUSize option.
+const defaultLRUSize = 100000
+
+// Option configures an UnspentPool at construction time.
+type Option func(*UnspentPool)
+
+// WithMode sets the pool's memory-residency mode. Defaults to ModeFull.
+func WithMode(mode Mode) Option {
+	return func(up *UnspentPool) {
+		up.mode = mode
+	}
+}
+
+// WithLRUSize sets the number of uxouts kept in memory under ModeLRU.
+// Ignored in other modes.
+func WithLRUSize(size int) Option {
+	return func(up *UnspentPool) {
+		up.lruSize = size
+	}
+}
@@ -0,0 +1,47 @@
+package blockdb
+
+import (
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUnspentPoolModeLRU checks that a uxout evicted from the bounded LRU
+// is still served correctly from bolt, and that the LRU is repopulated on
+// that fallback read.
+func TestUnspentPoolModeLRU(t *testing.T) {
+	db, cleanup := newTestDB(t)
+	defer cleanup()
+
+	up, err := NewUnspentPool(db, WithMode(ModeLRU), WithLRUSize(1))
+	require.NoError(t, err)
+
+	addr := testAddress(t)
+	ux1 := makeUxOut(addr, 1)
+	ux2 := makeUxOut(addr, 2)
+
+	require.NoError(t, db.Update(func(tx *bolt.Tx) error {
+		up.Lock()
+		defer up.Unlock()
+		if err := up.addUx(tx, ux1); err != nil {
+			return err
+		}
+		return up.addUx(tx, ux2)
+	}))
+
+	// The LRU only holds one entry, so ux1 was evicted when ux2 was added.
+	// Get must still find it via bolt and repopulate the LRU.
+	got, ok := up.Get(ux1.Hash())
+	require.True(t, ok)
+	require.Equal(t, ux1.Hash(), got.Hash())
+
+	_, ok = up.lru.get(ux1.Hash().Hex())
+	require.True(t, ok)
+
+	got, ok = up.Get(ux2.Hash())
+	require.True(t, ok)
+	require.Equal(t, ux2.Hash(), got.Hash())
+
+	require.Equal(t, uint64(2), up.Len())
+}
@@ -0,0 +1,72 @@
+package blockdb
+
+import (
+	"github.com/boltdb/bolt"
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// spentUxOut is a uxout that has been spent, tagged with the height of the
+// block whose transactions consumed it. Only populated under ModeArchive.
+type spentUxOut struct {
+	Ux     coin.UxOut
+	Height uint64
+}
+
+type spentPool struct {
+	*bolt.Bucket
+}
+
+func (sp spentPool) get(hash cipher.SHA256) (spentUxOut, bool, error) {
+	v := sp.Get(hash[:])
+	if v == nil {
+		return spentUxOut{}, false, nil
+	}
+
+	var s spentUxOut
+	if err := encoder.DeserializeRaw(v, &s); err != nil {
+		return spentUxOut{}, false, err
+	}
+	return s, true, nil
+}
+
+func (sp *spentPool) set(hash cipher.SHA256, ux coin.UxOut, height uint64) error {
+	return sp.Put(hash[:], encoder.Serialize(spentUxOut{Ux: ux, Height: height}))
+}
+
+func (sp *spentPool) delete(hash cipher.SHA256) error {
+	return sp.Delete(hash[:])
+}
+
+// GetSpent returns the uxout of hash and the height at which it was spent,
+// if the pool is running in ModeArchive and the output has been spent.
+func (up *UnspentPool) GetSpent(hash cipher.SHA256) (coin.UxOut, uint64, bool) {
+	if up.mode != ModeArchive {
+		return coin.UxOut{}, 0, false
+	}
+
+	var (
+		s  spentUxOut
+		ok bool
+	)
+	if err := up.db.View(func(tx *bolt.Tx) error {
+		var err error
+		s, ok, err = (spentPool{tx.Bucket(up.spentPool.Name)}).get(hash)
+		return err
+	}); err != nil {
+		return coin.UxOut{}, 0, false
+	}
+
+	return s.Ux, s.Height, ok
+}
+
+// spentAddedJournal records that ux was archived into the spent pool when
+// it was consumed.
+type spentAddedJournal struct {
+	ux coin.UxOut
+}
+
+func (e spentAddedJournal) revert(up *UnspentPool, tx *bolt.Tx) error {
+	return (spentPool{tx.Bucket(up.spentPool.Name)}).delete(e.ux.Hash())
+}
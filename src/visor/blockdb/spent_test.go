@@ -0,0 +1,120 @@
+package blockdb
+
+import (
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnspentPoolModeArchiveGetSpent(t *testing.T) {
+	db, cleanup := newTestDB(t)
+	defer cleanup()
+
+	up, err := NewUnspentPool(db, WithMode(ModeArchive))
+	require.NoError(t, err)
+
+	addr := testAddress(t)
+	ux := makeUxOut(addr, 1)
+
+	require.NoError(t, db.Update(func(tx *bolt.Tx) error {
+		up.Lock()
+		defer up.Unlock()
+		return up.addUx(tx, ux)
+	}))
+
+	// Not spent yet: GetSpent must not find it.
+	_, _, ok := up.GetSpent(ux.Hash())
+	require.False(t, ok)
+
+	const spendHeight = 42
+	require.NoError(t, db.Update(func(tx *bolt.Tx) error {
+		up.Lock()
+		defer up.Unlock()
+		return up.deleteUx(tx, ux, spendHeight)
+	}))
+
+	spent, height, ok := up.GetSpent(ux.Hash())
+	require.True(t, ok)
+	require.Equal(t, ux.Hash(), spent.Hash())
+	require.Equal(t, uint64(spendHeight), height)
+}
+
+func TestUnspentPoolGetSpentUnknownHash(t *testing.T) {
+	db, cleanup := newTestDB(t)
+	defer cleanup()
+
+	up, err := NewUnspentPool(db, WithMode(ModeArchive))
+	require.NoError(t, err)
+
+	addr := testAddress(t)
+	ux := makeUxOut(addr, 1)
+
+	_, _, ok := up.GetSpent(ux.Hash())
+	require.False(t, ok)
+}
+
+func TestUnspentPoolGetSpentOutsideArchiveMode(t *testing.T) {
+	db, cleanup := newTestDB(t)
+	defer cleanup()
+
+	up, err := NewUnspentPool(db)
+	require.NoError(t, err)
+
+	addr := testAddress(t)
+	ux := makeUxOut(addr, 1)
+
+	require.NoError(t, db.Update(func(tx *bolt.Tx) error {
+		up.Lock()
+		defer up.Unlock()
+		if err := up.addUx(tx, ux); err != nil {
+			return err
+		}
+		return up.deleteUx(tx, ux, 1)
+	}))
+
+	// GetSpent is only meaningful under ModeArchive; outside it, it must
+	// never report a spent uxout even though nothing archives it either.
+	_, _, ok := up.GetSpent(ux.Hash())
+	require.False(t, ok)
+}
+
+// TestUnspentPoolArchiveRevertRemovesSpentEntry checks that reverting a
+// deleteUx under ModeArchive also undoes the spent-pool archival, via
+// spentAddedJournal.
+func TestUnspentPoolArchiveRevertRemovesSpentEntry(t *testing.T) {
+	db, cleanup := newTestDB(t)
+	defer cleanup()
+
+	up, err := NewUnspentPool(db, WithMode(ModeArchive))
+	require.NoError(t, err)
+
+	addr := testAddress(t)
+	ux := makeUxOut(addr, 1)
+
+	require.NoError(t, db.Update(func(tx *bolt.Tx) error {
+		up.Lock()
+		defer up.Unlock()
+		return up.addUx(tx, ux)
+	}))
+
+	snapshot := up.Snapshot()
+
+	require.NoError(t, db.Update(func(tx *bolt.Tx) error {
+		up.Lock()
+		defer up.Unlock()
+		return up.deleteUx(tx, ux, 1)
+	}))
+
+	_, _, ok := up.GetSpent(ux.Hash())
+	require.True(t, ok)
+
+	require.NoError(t, up.RevertToSnapshot(snapshot))
+
+	_, _, ok = up.GetSpent(ux.Hash())
+	require.False(t, ok)
+
+	reverted, ok := up.Get(ux.Hash())
+	require.True(t, ok)
+	require.Equal(t, ux.Hash(), reverted.Hash())
+}
@@ -15,6 +15,10 @@ var (
 	xorhashKey = []byte("xorhash")
 )
 
+// addrUxKeys is the set of uxout hashes owned by a single address, as
+// stored in the unspent_addr_index bucket
+type addrUxKeys []cipher.SHA256
+
 // UnspentGetter provides unspend pool related
 // querying methods
 type UnspentGetter interface {
@@ -25,13 +29,22 @@ type UnspentGetter interface {
 
 // UnspentPool unspent outputs pool
 type UnspentPool struct {
-	db    *bolt.DB
-	pool  *bucket.Bucket
-	meta  *bucket.Bucket
-	cache struct {
-		pool   map[string]coin.UxOut
-		uxhash cipher.SHA256
+	db        *bolt.DB
+	pool      *bucket.Bucket
+	meta      *bucket.Bucket
+	addrIndex *bucket.Bucket
+	spentPool *bucket.Bucket
+	mode      Mode
+	lruSize   int
+	cache     struct {
+		pool      map[string]coin.UxOut
+		uxhash    cipher.SHA256
+		addrIndex map[cipher.Address]map[cipher.SHA256]struct{}
+		count     uint64
 	}
+	lru     *uxLRU
+	journal journal
+	bloom   *bloomFilter
 	sync.Mutex
 }
 
@@ -77,10 +90,73 @@ func (uo *uxOuts) delete(hash cipher.SHA256) error {
 	return uo.Delete(hash[:])
 }
 
-// NewUnspentPool creates new unspent pool instance
-func NewUnspentPool(db *bolt.DB) (*UnspentPool, error) {
-	up := &UnspentPool{db: db}
+type addrUxIndex struct {
+	*bolt.Bucket
+}
+
+func (ai addrUxIndex) get(addr cipher.Address) (addrUxKeys, error) {
+	v := ai.Get(addr.Bytes())
+	if v == nil {
+		return nil, nil
+	}
+
+	var keys addrUxKeys
+	if err := encoder.DeserializeRaw(v, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (ai *addrUxIndex) set(addr cipher.Address, keys addrUxKeys) error {
+	if len(keys) == 0 {
+		return ai.Delete(addr.Bytes())
+	}
+	return ai.Put(addr.Bytes(), encoder.Serialize(keys))
+}
+
+// add appends hash to the uxout set owned by addr
+func (ai *addrUxIndex) add(addr cipher.Address, hash cipher.SHA256) error {
+	keys, err := ai.get(addr)
+	if err != nil {
+		return err
+	}
+
+	keys = append(keys, hash)
+	return ai.set(addr, keys)
+}
+
+// remove removes hash from the uxout set owned by addr
+func (ai *addrUxIndex) remove(addr cipher.Address, hash cipher.SHA256) error {
+	keys, err := ai.get(addr)
+	if err != nil {
+		return err
+	}
+
+	for i, k := range keys {
+		if k == hash {
+			keys = append(keys[:i], keys[i+1:]...)
+			break
+		}
+	}
+
+	return ai.set(addr, keys)
+}
+
+// NewUnspentPool creates new unspent pool instance. By default it runs in
+// ModeFull; pass WithMode to select ModeLRU or ModeArchive.
+func NewUnspentPool(db *bolt.DB, options ...Option) (*UnspentPool, error) {
+	up := &UnspentPool{db: db, lruSize: defaultLRUSize}
+	for _, opt := range options {
+		opt(up)
+	}
+
 	up.cache.pool = make(map[string]coin.UxOut)
+	up.cache.addrIndex = make(map[cipher.Address]map[cipher.SHA256]struct{})
+	up.bloom = newBloomFilter()
+
+	if up.mode == ModeLRU {
+		up.lru = newUxLRU(up.lruSize)
+	}
 
 	pool, err := bucket.New([]byte("unspent_pool"), db)
 	if err != nil {
@@ -94,6 +170,18 @@ func NewUnspentPool(db *bolt.DB) (*UnspentPool, error) {
 	}
 	up.meta = meta
 
+	addrIndex, err := bucket.New([]byte("unspent_addr_index"), db)
+	if err != nil {
+		return nil, err
+	}
+	up.addrIndex = addrIndex
+
+	spentPool, err := bucket.New([]byte("spent_pool"), db)
+	if err != nil {
+		return nil, err
+	}
+	up.spentPool = spentPool
+
 	// load from db
 	if err := up.syncCache(); err != nil {
 		return nil, err
@@ -103,19 +191,11 @@ func NewUnspentPool(db *bolt.DB) (*UnspentPool, error) {
 }
 
 func (up *UnspentPool) syncCache() error {
-	// load unspent outputs
-	if err := up.pool.ForEach(func(k, v []byte) error {
-		var hash cipher.SHA256
-		copy(hash[:], k[:])
-
-		var ux coin.UxOut
-		if err := encoder.DeserializeRaw(v, &ux); err != nil {
-			return fmt.Errorf("load unspent outputs from db failed: %v", err)
+	if up.mode == ModeLRU {
+		if err := up.syncCacheLRU(); err != nil {
+			return err
 		}
-
-		up.cache.pool[hash.Hex()] = ux
-		return nil
-	}); err != nil {
+	} else if err := up.syncCacheFull(); err != nil {
 		return err
 	}
 
@@ -129,101 +209,344 @@ func (up *UnspentPool) syncCache() error {
 	return nil
 }
 
+// syncCacheFull loads every uxout body into memory, used by ModeFull and
+// ModeArchive.
+func (up *UnspentPool) syncCacheFull() error {
+	return up.pool.ForEach(func(k, v []byte) error {
+		var hash cipher.SHA256
+		copy(hash[:], k[:])
+
+		var ux coin.UxOut
+		if err := encoder.DeserializeRaw(v, &ux); err != nil {
+			return fmt.Errorf("load unspent outputs from db failed: %v", err)
+		}
+
+		up.addUxToCache([]coin.UxOut{ux})
+		return nil
+	})
+}
+
+// syncCacheLRU rebuilds the address index, uxout count and bloom filter
+// from the persisted address index without deserializing every uxout
+// body, so ModeLRU nodes don't pay for the full live set at startup.
+func (up *UnspentPool) syncCacheLRU() error {
+	return up.addrIndex.ForEach(func(k, v []byte) error {
+		addr, err := cipher.AddressFromBytes(k)
+		if err != nil {
+			return err
+		}
+
+		var keys addrUxKeys
+		if err := encoder.DeserializeRaw(v, &keys); err != nil {
+			return err
+		}
+
+		for _, h := range keys {
+			up.addUxToAddrIndexCache(addr, h)
+			up.bloom.add(h)
+			up.cache.count++
+		}
+		return nil
+	})
+}
+
+// processBlock applies a block's transactions to the pool inside a single
+// bolt transaction. Every mutation is journaled, so a mid-block failure
+// reverts cache, bolt state and xorhash back to the start of the block
+// without relying on the surrounding bolt transaction to undo the
+// in-memory side effects. The returned Rollback reverts the whole block; it
+// is only ever called by the caller while tx is still open, so it reuses
+// tx rather than opening a second write transaction, which would deadlock
+// against bolt's single-writer lock.
 func (up *UnspentPool) processBlock(b *coin.Block) bucket.TxHandler {
 	return func(tx *bolt.Tx) (bucket.Rollback, error) {
-		var (
-			delUxs    []coin.UxOut
-			addUxs    []coin.UxOut
-			uxHash    cipher.SHA256
-			oldUxHash = up.cache.uxhash
-		)
+		up.Lock()
+		defer up.Unlock()
+
+		// Forget the entries from a previously applied block here instead
+		// of carrying them for the life of the process, so the journal
+		// stays bounded to at most one block. After this reset, that
+		// block's Rollback panics and any SnapshotID taken before it is
+		// rejected as stale.
+		up.journal.reset()
+		blockSnapshot := SnapshotID{generation: up.journal.generation}
 
 		for _, txn := range b.Body.Transactions {
-			// get uxouts need to be deleted
-			uxs, err := up.getArray(txn.In)
+			// Revert to the start of the block, not just this
+			// transaction: once this handler returns an error the caller
+			// treats the whole block as failed and never calls the
+			// returned Rollback, so any earlier transactions in this
+			// block must be undone here too or they'd stay applied in
+			// the cache forever.
+			uxs, err := up.getArrayTx(tx, txn.In)
 			if err != nil {
+				up.revertToSnapshot(blockSnapshot, tx)
 				return func() {}, err
 			}
 
-			delUxs = append(delUxs, uxs...)
-
-			// Remove spent outputs
-			if _, err = up.deleteWithTx(tx, txn.In); err != nil {
-				return func() {}, err
+			for _, ux := range uxs {
+				if err := up.deleteUx(tx, ux, b.Head.BkSeq); err != nil {
+					up.revertToSnapshot(blockSnapshot, tx)
+					return func() {}, err
+				}
 			}
 
-			// Create new outputs
-			txUxs := coin.CreateUnspents(b.Head, txn)
-			addUxs = append(addUxs, txUxs...)
-			for i := range txUxs {
-				uxHash, err = up.addWithTx(tx, txUxs[i])
-				if err != nil {
+			for _, ux := range coin.CreateUnspents(b.Head, txn) {
+				if err := up.addUx(tx, ux); err != nil {
+					up.revertToSnapshot(blockSnapshot, tx)
 					return func() {}, err
 				}
 			}
 		}
 
-		// update caches
-		up.Lock()
-		up.deleteUxFromCache(delUxs)
-		up.addUxToCache(addUxs)
-		up.updateUxHashInCache(uxHash)
-		up.Unlock()
-
 		return func() {
 			up.Lock()
-			// reverse the cache
-			up.deleteUxFromCache(addUxs)
-			up.addUxToCache(delUxs)
-			up.updateUxHashInCache(oldUxHash)
-			up.Unlock()
+			defer up.Unlock()
+			if err := up.revertToSnapshot(blockSnapshot, tx); err != nil {
+				panic(fmt.Sprintf("unspent pool: failed to revert block: %v", err))
+			}
 		}, nil
 	}
 }
 
-func (up *UnspentPool) addWithTx(tx *bolt.Tx, ux coin.UxOut) (uxhash cipher.SHA256, err error) {
-	// will rollback all updates if return is not nil
-	// in case of unexpected panic, we must catch it and return error
-	defer func() {
-		if r := recover(); r != nil {
-			err = fmt.Errorf("unspent pool add uxout failed: %v", err)
+// SnapshotID identifies a point in the pool's journal. It is tagged with
+// the journal's generation at the time it was taken, so a SnapshotID from
+// before the journal was reset (e.g. by the start of the next block) is
+// recognized as stale instead of being replayed against entries it was
+// never part of.
+type SnapshotID struct {
+	generation int
+	id         int
+}
+
+// Snapshot returns an identifier for the current state of the pool's
+// journal. Pass it to RevertToSnapshot to undo every mutation recorded
+// since this call, as long as the journal hasn't been reset in the
+// meantime.
+func (up *UnspentPool) Snapshot() SnapshotID {
+	up.Lock()
+	defer up.Unlock()
+	return SnapshotID{generation: up.journal.generation, id: up.journal.length()}
+}
+
+// RevertToSnapshot replays the journal in reverse back to s, restoring
+// both the in-memory cache and the on-disk state. It opens its own bolt
+// transaction, so it is safe to call after the transaction that produced
+// the journaled entries has already committed.
+func (up *UnspentPool) RevertToSnapshot(s SnapshotID) error {
+	return up.db.Update(func(tx *bolt.Tx) error {
+		up.Lock()
+		defer up.Unlock()
+		return up.revertToSnapshot(s, tx)
+	})
+}
+
+// revertToSnapshot replays the journal in reverse back to s using tx.
+// Callers must hold up.Mutex and supply a writable transaction over the
+// pool's buckets. It returns an error, rather than panicking or silently
+// replaying the wrong entries, if s belongs to a journal generation that
+// has since been reset or otherwise falls outside the current journal.
+func (up *UnspentPool) revertToSnapshot(s SnapshotID, tx *bolt.Tx) error {
+	if s.generation != up.journal.generation {
+		return fmt.Errorf("unspent pool: stale snapshot: journal has been reset since it was taken")
+	}
+	if s.id < 0 || s.id > up.journal.length() {
+		return fmt.Errorf("unspent pool: invalid snapshot: id %d out of range [0, %d]", s.id, up.journal.length())
+	}
+
+	for i := up.journal.length() - 1; i >= s.id; i-- {
+		if err := up.journal.entries[i].revert(up, tx); err != nil {
+			return err
 		}
-	}()
+	}
+	up.journal.entries = up.journal.entries[:s.id]
+	return nil
+}
 
-	// check if the uxout does exist in the pool
+// addUx inserts ux into the pool, updating the xorhash checksum and
+// address index, and journals the mutation so it can be reverted.
+// Callers must hold up.Mutex.
+func (up *UnspentPool) addUx(tx *bolt.Tx, ux coin.UxOut) error {
 	h := ux.Hash()
-	if up.Contains(h) {
-		return cipher.SHA256{}, fmt.Errorf("attemps to insert uxout:%v twice into the unspent pool", h.Hex())
+	exists, err := up.uxExistsTx(tx, h)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("attemps to insert uxout:%v twice into the unspent pool", h.Hex())
 	}
 
 	meta := unspentMeta{tx.Bucket(up.meta.Name)}
 	xorhash, err := meta.getXorHash()
 	if err != nil {
-		return cipher.SHA256{}, err
+		return err
 	}
 
+	prevXorhash := up.cache.uxhash
 	xorhash = xorhash.Xor(ux.SnapshotHash())
 	if err := meta.setXorHash(xorhash); err != nil {
-		return cipher.SHA256{}, err
+		return err
 	}
 
-	err = uxOuts{tx.Bucket(up.pool.Name)}.set(h, ux)
+	if err := (uxOuts{tx.Bucket(up.pool.Name)}).set(h, ux); err != nil {
+		return err
+	}
+
+	if err := (addrUxIndex{tx.Bucket(up.addrIndex.Name)}).add(ux.Body.Address, h); err != nil {
+		return err
+	}
+
+	up.addUxToCache([]coin.UxOut{ux})
+	up.updateUxHashInCache(xorhash)
+
+	up.journal.append(xorHashJournal{prev: prevXorhash})
+	up.journal.append(uxAddedJournal{ux: ux})
+
+	return nil
+}
+
+// deleteUx removes ux from the pool, updating the xorhash checksum and
+// address index, and journals the mutation so it can be reverted. Under
+// ModeArchive, ux is also archived into the spent pool tagged with
+// height. Callers must hold up.Mutex.
+func (up *UnspentPool) deleteUx(tx *bolt.Tx, ux coin.UxOut, height uint64) error {
+	h := ux.Hash()
+
+	meta := unspentMeta{tx.Bucket(up.meta.Name)}
+	xorhash, err := meta.getXorHash()
 	if err != nil {
-		return cipher.SHA256{}, err
+		return err
+	}
+
+	prevXorhash := up.cache.uxhash
+	xorhash = xorhash.Xor(ux.SnapshotHash())
+	if err := meta.setXorHash(xorhash); err != nil {
+		return err
+	}
+
+	if err := (uxOuts{tx.Bucket(up.pool.Name)}).delete(h); err != nil {
+		return err
+	}
+
+	if err := (addrUxIndex{tx.Bucket(up.addrIndex.Name)}).remove(ux.Body.Address, h); err != nil {
+		return err
+	}
+
+	if up.mode == ModeArchive {
+		if err := (spentPool{tx.Bucket(up.spentPool.Name)}).set(h, ux, height); err != nil {
+			return err
+		}
+		up.journal.append(spentAddedJournal{ux: ux})
+	}
+
+	up.deleteUxFromCache([]coin.UxOut{ux})
+	up.updateUxHashInCache(xorhash)
+
+	up.journal.append(xorHashJournal{prev: prevXorhash})
+	up.journal.append(uxDeletedJournal{ux: ux})
+
+	return nil
+}
+
+// uxExistsTx reports whether h is a live output in the pool, consulting
+// bolt directly under ModeLRU since the in-memory cache may have evicted
+// it. Callers must hold up.Mutex.
+func (up *UnspentPool) uxExistsTx(tx *bolt.Tx, h cipher.SHA256) (bool, error) {
+	if up.mode != ModeLRU {
+		_, ok := up.cache.pool[h.Hex()]
+		return ok, nil
+	}
+
+	if _, ok := up.lru.get(h.Hex()); ok {
+		return true, nil
 	}
 
-	return xorhash, nil
+	_, ok, err := (uxOuts{tx.Bucket(up.pool.Name)}).get(h)
+	return ok, err
+}
+
+// getArrayTx resolves hashes to uxouts, consulting bolt directly under
+// ModeLRU on a cache miss and repopulating the LRU. Callers must hold
+// up.Mutex.
+func (up *UnspentPool) getArrayTx(tx *bolt.Tx, hashes []cipher.SHA256) (coin.UxArray, error) {
+	uxs := make(coin.UxArray, 0, len(hashes))
+	for _, h := range hashes {
+		if up.mode != ModeLRU {
+			ux, ok := up.cache.pool[h.Hex()]
+			if !ok {
+				return nil, fmt.Errorf("unspent output of %s does not exist", h.Hex())
+			}
+			uxs = append(uxs, ux)
+			continue
+		}
+
+		if ux, ok := up.lru.get(h.Hex()); ok {
+			uxs = append(uxs, ux)
+			continue
+		}
+
+		v, ok, err := (uxOuts{tx.Bucket(up.pool.Name)}).get(h)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("unspent output of %s does not exist", h.Hex())
+		}
+
+		up.lru.add(h.Hex(), *v)
+		uxs = append(uxs, *v)
+	}
+	return uxs, nil
 }
 
 func (up *UnspentPool) deleteUxFromCache(uxs []coin.UxOut) {
 	for _, ux := range uxs {
-		delete(up.cache.pool, ux.Hash().Hex())
+		h := ux.Hash()
+		if up.mode == ModeLRU {
+			up.lru.remove(h.Hex())
+		} else {
+			delete(up.cache.pool, h.Hex())
+		}
+		up.deleteUxFromAddrIndexCache(ux.Body.Address, h)
+		up.bloom.remove(h)
+		up.cache.count--
 	}
 }
 
 func (up *UnspentPool) addUxToCache(uxs []coin.UxOut) {
 	for i, ux := range uxs {
-		up.cache.pool[ux.Hash().Hex()] = uxs[i]
+		h := ux.Hash()
+		if up.mode == ModeLRU {
+			up.lru.add(h.Hex(), uxs[i])
+		} else {
+			up.cache.pool[h.Hex()] = uxs[i]
+		}
+		up.addUxToAddrIndexCache(ux.Body.Address, h)
+		up.bloom.add(h)
+		up.cache.count++
+	}
+}
+
+// addUxToAddrIndexCache adds hash to the in-memory index of uxouts owned by addr
+func (up *UnspentPool) addUxToAddrIndexCache(addr cipher.Address, hash cipher.SHA256) {
+	hashes, ok := up.cache.addrIndex[addr]
+	if !ok {
+		hashes = make(map[cipher.SHA256]struct{})
+		up.cache.addrIndex[addr] = hashes
+	}
+	hashes[hash] = struct{}{}
+}
+
+// deleteUxFromAddrIndexCache removes hash from the in-memory index of uxouts owned by addr
+func (up *UnspentPool) deleteUxFromAddrIndexCache(addr cipher.Address, hash cipher.SHA256) {
+	hashes, ok := up.cache.addrIndex[addr]
+	if !ok {
+		return
+	}
+
+	delete(hashes, hash)
+	if len(hashes) == 0 {
+		delete(up.cache.addrIndex, addr)
 	}
 }
 
@@ -234,136 +557,156 @@ func (up *UnspentPool) updateUxHashInCache(hash cipher.SHA256) {
 // GetArray returns UxOut by given hash array, will return error when
 // if any of the hashes is not exist.
 func (up *UnspentPool) GetArray(hashes []cipher.SHA256) (coin.UxArray, error) {
-	up.Lock()
-	defer up.Unlock()
-	return up.getArray(hashes)
-}
-
-func (up *UnspentPool) getArray(hashes []cipher.SHA256) (coin.UxArray, error) {
 	uxs := make(coin.UxArray, 0, len(hashes))
-	for i := range hashes {
-		ux, ok := up.cache.pool[hashes[i].Hex()]
+	for _, h := range hashes {
+		ux, ok := up.Get(h)
 		if !ok {
-			return nil, fmt.Errorf("unspent output of %s does not exist", hashes[i].Hex())
+			return nil, fmt.Errorf("unspent output of %s does not exist", h.Hex())
 		}
-
 		uxs = append(uxs, ux)
 	}
 	return uxs, nil
 }
 
-// Get returns the uxout value of given hash
+// Get returns the uxout value of given hash. Under ModeLRU, a miss in the
+// bounded cache falls through to bolt and repopulates the LRU.
 func (up *UnspentPool) Get(h cipher.SHA256) (coin.UxOut, bool) {
 	up.Lock()
-	ux, ok := up.cache.pool[h.Hex()]
-	up.Unlock()
-
-	return ux, ok
-}
+	defer up.Unlock()
 
-// GetAll returns Pool as an array. Note: they are not in any particular order.
-func (up *UnspentPool) GetAll() (coin.UxArray, error) {
-	up.Lock()
-	arr := make(coin.UxArray, 0, len(up.cache.pool))
-	for _, ux := range up.cache.pool {
-		arr = append(arr, ux)
+	if up.mode != ModeLRU {
+		ux, ok := up.cache.pool[h.Hex()]
+		return ux, ok
 	}
-	up.Unlock()
-
-	return arr, nil
-}
 
-// delete delete unspent of given hashes
-func (up *UnspentPool) deleteWithTx(tx *bolt.Tx, hashes []cipher.SHA256) (cipher.SHA256, error) {
-	uxouts := uxOuts{tx.Bucket(up.pool.Name)}
-	meta := unspentMeta{tx.Bucket(up.meta.Name)}
-	var uxHash cipher.SHA256
-	for _, hash := range hashes {
-		ux, ok, err := uxouts.get(hash)
-		if err != nil {
-			return cipher.SHA256{}, err
-		}
-
-		if !ok {
-			continue
-		}
+	if ux, ok := up.lru.get(h.Hex()); ok {
+		return ux, true
+	}
 
-		uxHash, err = meta.getXorHash()
-		if err != nil {
-			return cipher.SHA256{}, err
+	// Hold the lock across the bolt read and the LRU insert below: a
+	// concurrent deleteUx for h between an unlock here and a later re-lock
+	// could otherwise resurrect a spent output by writing the stale read
+	// result into the LRU after it was removed.
+	var (
+		ux    coin.UxOut
+		found bool
+	)
+	if err := up.db.View(func(tx *bolt.Tx) error {
+		v, ok, err := (uxOuts{tx.Bucket(up.pool.Name)}).get(h)
+		if err != nil || !ok {
+			return err
 		}
+		ux, found = *v, true
+		return nil
+	}); err != nil || !found {
+		return coin.UxOut{}, false
+	}
 
-		uxHash = uxHash.Xor(ux.SnapshotHash())
-
-		// update uxhash
-		if err = meta.setXorHash(uxHash); err != nil {
-			return cipher.SHA256{}, err
-		}
+	up.lru.add(h.Hex(), ux)
+	return ux, true
+}
 
-		if err := uxouts.delete(hash); err != nil {
-			return cipher.SHA256{}, err
+// GetAll returns Pool as an array. Note: they are not in any particular order.
+func (up *UnspentPool) GetAll() (coin.UxArray, error) {
+	if up.mode != ModeLRU {
+		up.Lock()
+		arr := make(coin.UxArray, 0, len(up.cache.pool))
+		for _, ux := range up.cache.pool {
+			arr = append(arr, ux)
 		}
+		up.Unlock()
+		return arr, nil
 	}
 
-	return uxHash, nil
+	var arr coin.UxArray
+	err := up.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(up.pool.Name).ForEach(func(k, v []byte) error {
+			var ux coin.UxOut
+			if err := encoder.DeserializeRaw(v, &ux); err != nil {
+				return err
+			}
+			arr = append(arr, ux)
+			return nil
+		})
+	})
+	return arr, err
 }
 
 // Len returns the unspent outputs num
 func (up *UnspentPool) Len() uint64 {
 	up.Lock()
 	defer up.Unlock()
-	return uint64(len(up.cache.pool))
+	return up.cache.count
 }
 
-// Collides checks for hash collisions with existing hashes
+// Collides checks for hash collisions with existing hashes. The bloom
+// filter is probed lock-free first; only a hit falls through to Contains.
 func (up *UnspentPool) Collides(hashes []cipher.SHA256) bool {
-	up.Lock()
 	for i := range hashes {
-		if _, ok := up.cache.pool[hashes[i].Hex()]; ok {
+		if up.bloom.mayContain(hashes[i]) && up.Contains(hashes[i]) {
 			return true
 		}
 	}
-	up.Unlock()
 	return false
 }
 
-// Contains check if the hash of uxout does exist in the pool
+// Contains check if the hash of uxout does exist in the pool. The bloom
+// filter is probed lock-free first; only a hit falls through to Get.
 func (up *UnspentPool) Contains(h cipher.SHA256) bool {
-	up.Lock()
-	_, ok := up.cache.pool[h.Hex()]
-	defer up.Unlock()
+	if !up.bloom.mayContain(h) {
+		return false
+	}
+
+	_, ok := up.Get(h)
 	return ok
 }
 
 // GetUnspentsOfAddr returns all unspent outputs of given address
 func (up *UnspentPool) GetUnspentsOfAddr(addr cipher.Address) coin.UxArray {
 	up.Lock()
-	uxs := make(coin.UxArray, 0, len(up.cache.pool))
-	for _, ux := range up.cache.pool {
-		if ux.Body.Address == addr {
+	hashes := make([]cipher.SHA256, 0, len(up.cache.addrIndex[addr]))
+	for h := range up.cache.addrIndex[addr] {
+		hashes = append(hashes, h)
+	}
+	up.Unlock()
+
+	uxs := make(coin.UxArray, 0, len(hashes))
+	for _, h := range hashes {
+		if ux, ok := up.Get(h); ok {
 			uxs = append(uxs, ux)
 		}
 	}
-	up.Unlock()
 	return uxs
 }
 
 // GetUnspentsOfAddrs returns unspent outputs map of given addresses,
-// the address as return map key, unspent outputs as value.
+// the address as return map key, unspent outputs as value. It uses the
+// address index for an O(k) lookup where k is the outputs of the queried
+// addresses.
 func (up *UnspentPool) GetUnspentsOfAddrs(addrs []cipher.Address) coin.AddressUxOuts {
 	up.Lock()
-	addrm := make(map[cipher.Address]struct{}, len(addrs))
+	addrHashes := make(map[cipher.Address][]cipher.SHA256, len(addrs))
 	for _, a := range addrs {
-		addrm[a] = struct{}{}
+		hashes := up.cache.addrIndex[a]
+		if len(hashes) == 0 {
+			continue
+		}
+		hs := make([]cipher.SHA256, 0, len(hashes))
+		for h := range hashes {
+			hs = append(hs, h)
+		}
+		addrHashes[a] = hs
 	}
+	up.Unlock()
 
 	addrUxs := coin.AddressUxOuts{}
-	for _, ux := range up.cache.pool {
-		if _, ok := addrm[ux.Body.Address]; ok {
-			addrUxs[ux.Body.Address] = append(addrUxs[ux.Body.Address], ux)
+	for a, hashes := range addrHashes {
+		for _, h := range hashes {
+			if ux, ok := up.Get(h); ok {
+				addrUxs[a] = append(addrUxs[a], ux)
+			}
 		}
 	}
-	up.Unlock()
 	return addrUxs
 }
 
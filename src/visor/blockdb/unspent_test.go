@@ -0,0 +1,87 @@
+package blockdb
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDB(t *testing.T) (*bolt.DB, func()) {
+	f, err := ioutil.TempFile("", "unspent_pool_test")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	db, err := bolt.Open(f.Name(), 0600, nil)
+	require.NoError(t, err)
+
+	return db, func() {
+		db.Close()
+		os.Remove(f.Name())
+	}
+}
+
+func testAddress(t *testing.T) cipher.Address {
+	pub, _ := cipher.GenerateKeyPair()
+	return cipher.AddressFromPubKey(pub)
+}
+
+// makeUxOut builds a uxout owned by addr. seed only needs to differ between
+// calls so the resulting uxouts hash to distinct values.
+func makeUxOut(addr cipher.Address, seed byte) coin.UxOut {
+	var srcTx cipher.SHA256
+	srcTx[0] = seed
+
+	return coin.UxOut{
+		Body: coin.UxBody{
+			SrcTransaction: srcTx,
+			Address:        addr,
+			Coins:          1e6,
+			Hours:          100,
+		},
+	}
+}
+
+func TestUnspentPoolAddrIndex(t *testing.T) {
+	db, cleanup := newTestDB(t)
+	defer cleanup()
+
+	up, err := NewUnspentPool(db)
+	require.NoError(t, err)
+
+	addr := testAddress(t)
+	ux1 := makeUxOut(addr, 1)
+	ux2 := makeUxOut(addr, 2)
+
+	require.NoError(t, db.Update(func(tx *bolt.Tx) error {
+		up.Lock()
+		defer up.Unlock()
+		if err := up.addUx(tx, ux1); err != nil {
+			return err
+		}
+		return up.addUx(tx, ux2)
+	}))
+
+	uxs := up.GetUnspentsOfAddr(addr)
+	require.Len(t, uxs, 2)
+
+	addrUxs := up.GetUnspentsOfAddrs([]cipher.Address{addr})
+	require.Len(t, addrUxs[addr], 2)
+
+	require.NoError(t, db.Update(func(tx *bolt.Tx) error {
+		up.Lock()
+		defer up.Unlock()
+		return up.deleteUx(tx, ux1, 1)
+	}))
+
+	uxs = up.GetUnspentsOfAddr(addr)
+	require.Len(t, uxs, 1)
+	require.Equal(t, ux2.Hash(), uxs[0].Hash())
+
+	addrUxs = up.GetUnspentsOfAddrs([]cipher.Address{addr})
+	require.Len(t, addrUxs[addr], 1)
+}